@@ -0,0 +1,147 @@
+package bulkhttpclient
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CacheStore persists cached response bodies, headers and status codes keyed
+// by an opaque string derived from the request. Implementations must be safe
+// for concurrent use; see NewInMemoryCache for the built-in default and
+// bulkhttpclient/cacheredis for a Redis-backed store.
+type CacheStore interface {
+	Get(ctx context.Context, key string) (body []byte, headers http.Header, statusCode int, found bool, err error)
+	Set(ctx context.Context, key string, body []byte, headers http.Header, statusCode int, ttl time.Duration) error
+}
+
+//CachePolicy controls which requests WithCache is eligible to cache and, for
+//responses that don't specify their own freshness lifetime, how long an
+//entry is kept in the store. Such entries are never served without a round
+//trip to the origin (a conditional one when they carry an ETag or
+//Last-Modified).
+type CachePolicy struct {
+	//Methods lists the HTTP methods eligible for caching. Defaults to GET
+	//and HEAD.
+	Methods []string
+
+	//VaryHeaders lists request header names folded into the cache key in
+	//addition to method and URL, e.g. "Accept" or "Authorization".
+	VaryHeaders []string
+
+	//DefaultTTL is used when a cached response carries no Cache-Control
+	//max-age or Expires header of its own.
+	DefaultTTL time.Duration
+}
+
+//WithCache opts a BulkClient into response caching backed by store and
+//governed by policy. Cache hits bypass the worker pool entirely.
+func WithCache(store CacheStore, policy CachePolicy) Option {
+	if len(policy.Methods) == 0 {
+		policy.Methods = []string{http.MethodGet, http.MethodHead}
+	}
+
+	return func(cl *BulkClient) {
+		cl.cache = store
+		cl.cachePolicy = policy
+	}
+}
+
+func (p CachePolicy) eligible(req *http.Request) bool {
+	for _, method := range p.Methods {
+		if strings.EqualFold(method, req.Method) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p CachePolicy) cacheKey(req *http.Request) string {
+	h := sha256.New()
+	h.Write([]byte(req.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(req.URL.String()))
+
+	for _, name := range p.VaryHeaders {
+		h.Write([]byte{0})
+		h.Write([]byte(name))
+		h.Write([]byte{'='})
+		h.Write([]byte(req.Header.Get(name)))
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type cacheControl struct {
+	noStore   bool
+	noCache   bool
+	maxAge    time.Duration
+	hasMaxAge bool
+}
+
+func parseCacheControl(headers http.Header) cacheControl {
+	var cc cacheControl
+
+	for _, directive := range strings.Split(headers.Get("Cache-Control"), ",") {
+		directive = strings.ToLower(strings.TrimSpace(directive))
+
+		switch {
+		case directive == "no-store":
+			cc.noStore = true
+		case directive == "no-cache":
+			cc.noCache = true
+		case strings.HasPrefix(directive, "max-age="):
+			if seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age=")); err == nil {
+				cc.maxAge = time.Duration(seconds) * time.Second
+				cc.hasMaxAge = true
+			}
+		}
+	}
+
+	return cc
+}
+
+//storable reports whether a response is eligible to be written to the
+//cache at all.
+func storable(headers http.Header) bool {
+	return !parseCacheControl(headers).noStore
+}
+
+//freshFor returns how long a response may be served from cache without
+//revalidation, and whether that lifetime could be determined. A Cache-Control
+//of no-cache forces immediate revalidation (a freshness lifetime of zero).
+func freshFor(headers http.Header) (time.Duration, bool) {
+	cc := parseCacheControl(headers)
+
+	if cc.noCache {
+		return 0, true
+	}
+
+	if cc.hasMaxAge {
+		return cc.maxAge, true
+	}
+
+	if expires := headers.Get("Expires"); expires != "" {
+		if when, err := http.ParseTime(expires); err == nil {
+			return when.Sub(responseDate(headers)), true
+		}
+	}
+
+	return 0, false
+}
+
+//responseDate is the reference point freshness lifetimes are measured from:
+//the response's own Date header, falling back to now if absent.
+func responseDate(headers http.Header) time.Time {
+	if date := headers.Get("Date"); date != "" {
+		if when, err := http.ParseTime(date); err == nil {
+			return when
+		}
+	}
+
+	return time.Now()
+}
@@ -0,0 +1,120 @@
+package bulkhttpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo_CacheHitSkipsOrigin(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second, WithCache(NewInMemoryCache(10), CachePolicy{}))
+
+	req1, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	first := NewBulkRequest().AddRequest(req1)
+	if _, errs := cl.Do(first); errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+	first.CloseAllResponses()
+
+	req2, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	second := NewBulkRequest().AddRequest(req2)
+	responses, errs := cl.Do(second)
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+	if responses[0].StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 from cache, got %d", responses[0].StatusCode)
+	}
+
+	if got := atomic.LoadInt32(&hits); got != 1 {
+		t.Fatalf("expected the origin to be hit once, got %d", got)
+	}
+
+	second.CloseAllResponses()
+}
+
+func TestDo_CacheHitPreservesOriginStatusCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second, WithCache(NewInMemoryCache(10), CachePolicy{}))
+
+	req1, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	first := NewBulkRequest().AddRequest(req1)
+	if _, errs := cl.Do(first); errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+	first.CloseAllResponses()
+
+	req2, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	second := NewBulkRequest().AddRequest(req2)
+	responses, errs := cl.Do(second)
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+
+	if responses[0].StatusCode != http.StatusNonAuthoritativeInfo {
+		t.Fatalf("expected the cached response to preserve the origin's %d status, got %d", http.StatusNonAuthoritativeInfo, responses[0].StatusCode)
+	}
+
+	second.CloseAllResponses()
+}
+
+func TestDo_CacheRevalidatesStaleEntryWith304(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("ETag", `"v1"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("body"))
+	}))
+	defer server.Close()
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second, WithCache(NewInMemoryCache(10), CachePolicy{}))
+
+	req1, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	first := NewBulkRequest().AddRequest(req1)
+	responses, errs := cl.Do(first)
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+	first.CloseAllResponses()
+
+	req2, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	second := NewBulkRequest().AddRequest(req2)
+	responses, errs = cl.Do(second)
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+
+	if responses[0].StatusCode != http.StatusOK {
+		t.Fatalf("expected the cached body to be served as 200, got %d", responses[0].StatusCode)
+	}
+
+	if got := atomic.LoadInt32(&hits); got != 2 {
+		t.Fatalf("expected the origin to be hit twice (miss + revalidate), got %d", got)
+	}
+
+	second.CloseAllResponses()
+}
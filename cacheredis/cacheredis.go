@@ -0,0 +1,61 @@
+//Package cacheredis provides a Redis-backed bulkhttpclient.CacheStore, kept
+//out of the core module so it stays free of the go-redis dependency.
+package cacheredis
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	bulkhttpclient "github.com/souravbadami/meniscus"
+)
+
+//Store is a bulkhttpclient.CacheStore backed by Redis.
+type Store struct {
+	client *redis.Client
+	prefix string
+}
+
+type entry struct {
+	Body       []byte      `json:"body"`
+	Headers    http.Header `json:"headers"`
+	StatusCode int         `json:"status_code"`
+}
+
+//New wraps client as a bulkhttpclient.CacheStore, namespacing every key
+//under prefix so the cache can share a Redis instance with other data.
+func New(client *redis.Client, prefix string) *Store {
+	return &Store{client: client, prefix: prefix}
+}
+
+//Get implements bulkhttpclient.CacheStore.
+func (s *Store) Get(ctx context.Context, key string) ([]byte, http.Header, int, bool, error) {
+	raw, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if err == redis.Nil {
+		return nil, nil, 0, false, nil
+	}
+	if err != nil {
+		return nil, nil, 0, false, err
+	}
+
+	var e entry
+	if err := json.Unmarshal(raw, &e); err != nil {
+		return nil, nil, 0, false, err
+	}
+
+	return e.Body, e.Headers, e.StatusCode, true, nil
+}
+
+//Set implements bulkhttpclient.CacheStore.
+func (s *Store) Set(ctx context.Context, key string, body []byte, headers http.Header, statusCode int, ttl time.Duration) error {
+	raw, err := json.Marshal(entry{Body: body, Headers: headers, StatusCode: statusCode})
+	if err != nil {
+		return err
+	}
+
+	return s.client.Set(ctx, s.prefix+key, raw, ttl).Err()
+}
+
+var _ bulkhttpclient.CacheStore = (*Store)(nil)
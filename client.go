@@ -1,13 +1,14 @@
 package bulkhttpclient
 
 import (
-	"net/http"
+	"bytes"
 	"context"
-	"time"
-	"fmt"
 	"errors"
+	"fmt"
 	"io/ioutil"
-	"bytes"
+	"net/http"
+	"sync"
+	"time"
 )
 
 //HTTPClient ...
@@ -22,15 +23,29 @@ type Request interface {
 
 //BulkClient ...
 type BulkClient struct {
-	httpclient HTTPClient
-	timeout    time.Duration
+	httpclient     HTTPClient
+	timeout        time.Duration
+	retryPolicy    RetryPolicy
+	fireWorkers    int
+	processWorkers int
+	queueSize      int
+	hostLimiter    *hostLimiter
+	observer       Observer
+	cache          CacheStore
+	cachePolicy    CachePolicy
+	streamBodies   bool
+	maxBuffered    int64
 }
 
 //RoundTrip ...
 type RoundTrip struct {
-	requests  []*http.Request
-	responses []*http.Response
-	errors    []error
+	requests      []*http.Request
+	responses     []*http.Response
+	errors        []error
+	retryPolicies []*RetryPolicy
+	timeouts      []time.Duration
+	cancel        context.CancelFunc
+	reqCancels    []context.CancelFunc // per-request timeout cancels, released by CloseAllResponses
 }
 
 //ErrNoRequests ...
@@ -40,8 +55,11 @@ var ErrNoRequests = errors.New("no requests provided")
 var ErrRequestIgnored = errors.New("request ignored")
 
 type requestParcel struct {
-	request *http.Request
-	index    int
+	request     *http.Request
+	index       int
+	retryPolicy *RetryPolicy
+	cacheKey    string
+	timeout     time.Duration
 }
 
 type responseParcel struct {
@@ -49,14 +67,29 @@ type responseParcel struct {
 	response *http.Response
 	err      error
 	index    int
+	cacheKey string
+	cancel   context.CancelFunc // releases the per-request timeout context, if AddRequestWithTimeout set one; kept alive until the body is consumed
 }
 
+//Option configures a BulkClient at construction time.
+type Option func(*BulkClient)
+
 //NewBulkHTTPClient ...
-func NewBulkHTTPClient(client HTTPClient, timeout time.Duration) *BulkClient {
-	return &BulkClient{
-		httpclient: client,
-		timeout:    timeout,
+func NewBulkHTTPClient(client HTTPClient, timeout time.Duration, opts ...Option) *BulkClient {
+	cl := &BulkClient{
+		httpclient:     client,
+		timeout:        timeout,
+		retryPolicy:    RetryPolicy{MaxAttempts: 1},
+		fireWorkers:    defaultWorkers,
+		processWorkers: defaultWorkers,
+		observer:       noopObserver{},
+	}
+
+	for _, opt := range opts {
+		opt(cl)
 	}
+
+	return cl
 }
 
 //NewBulkRequest ...
@@ -70,63 +103,252 @@ func NewBulkRequest() *RoundTrip {
 //AddRequest ...
 func (r *RoundTrip) AddRequest(request *http.Request) *RoundTrip {
 	r.requests = append(r.requests, request)
+	r.retryPolicies = append(r.retryPolicies, nil)
+	r.timeouts = append(r.timeouts, 0)
+	return r
+}
+
+//AddRequestWithRetry adds request to the RoundTrip, overriding the
+//BulkClient's RetryPolicy for this request only.
+func (r *RoundTrip) AddRequestWithRetry(request *http.Request, policy *RetryPolicy) *RoundTrip {
+	r.requests = append(r.requests, request)
+	r.retryPolicies = append(r.retryPolicies, policy)
+	r.timeouts = append(r.timeouts, 0)
 	return r
 }
 
-//Do ...
+// AddRequestWithTimeout adds request to the RoundTrip with its own timeout,
+// independent of the other requests in the batch: executeRequest derives a
+// context.WithTimeout(parentCtx, d) for this request alone, so one slow
+// request doesn't hold up or get cut short with its siblings.
+func (r *RoundTrip) AddRequestWithTimeout(request *http.Request, d time.Duration) *RoundTrip {
+	r.requests = append(r.requests, request)
+	r.retryPolicies = append(r.retryPolicies, nil)
+	r.timeouts = append(r.timeouts, d)
+	return r
+}
+
+func (r *RoundTrip) retryPolicyForIndex(index int) *RetryPolicy {
+	if index < len(r.retryPolicies) {
+		return r.retryPolicies[index]
+	}
+	return nil
+}
+
+func (r *RoundTrip) timeoutForIndex(index int) time.Duration {
+	if index < len(r.timeouts) {
+		return r.timeouts[index]
+	}
+	return 0
+}
+
+// Do fires every request in bulkRequest and blocks until all of them have
+// completed, using context.Background() bounded by the BulkClient's
+// configured timeout. For control over the parent context, use
+// DoWithContext. The context created internally here is released by
+// RoundTrip.CloseAllResponses rather than when Do returns, so a response
+// streamed via WithStreamingBodies is still readable afterwards.
 func (cl *BulkClient) Do(bulkRequest *RoundTrip) ([]*http.Response, []error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cl.timeout)
+	bulkRequest.cancel = cancel
+
+	return cl.DoWithContext(ctx, bulkRequest)
+}
+
+// DoWithContext fires every request in bulkRequest and blocks until all of
+// them have completed or ctx is done. Individual requests added via
+// RoundTrip.AddRequestWithTimeout still get their own, narrower deadline.
+func (cl *BulkClient) DoWithContext(ctx context.Context, bulkRequest *RoundTrip) ([]*http.Response, []error) {
 	noOfRequests := len(bulkRequest.requests)
 	if noOfRequests == 0 {
 		return nil, []error{ErrNoRequests}
 	}
 
-	bulkRequest.responses = make([]*http.Response, noOfRequests)
-	bulkRequest.errors    = make([]error, noOfRequests)
+	processedResponses := cl.dispatch(ctx, bulkRequest)
 
-	requestList        := make(chan requestParcel)
-	recievedResponses  := make(chan roundTripParcel)
-	processedResponses := make(chan roundTripParcel)
+	return cl.completionListener(ctx, bulkRequest, processedResponses)
+}
+
+//StreamedResponse carries the result of a single request within a RoundTrip as soon as it completes.
+type StreamedResponse struct {
+	Index    int
+	Response *http.Response
+	Err      error
+}
 
-	for nWorker := 0; nWorker < 10; nWorker++ {
-		go cl.fireRequests(requestList, recievedResponses)
+//DoStream fires every request in bulkRequest and delivers each response on the
+//returned channel as soon as it is ready, instead of blocking until the whole
+//batch finishes like Do. The channel is closed once every request has either
+// completed or been ignored because ctx was done. As with Do, the context
+// derived here is released by RoundTrip.CloseAllResponses, not when the
+// channel closes, so streamed bodies (see WithStreamingBodies) stay readable.
+func (cl *BulkClient) DoStream(ctx context.Context, bulkRequest *RoundTrip) (<-chan StreamedResponse, error) {
+	noOfRequests := len(bulkRequest.requests)
+	if noOfRequests == 0 {
+		return nil, ErrNoRequests
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), cl.timeout)
-	defer cancel()
+	ctx, cancel := context.WithCancel(ctx)
+	bulkRequest.cancel = cancel
+
+	processedResponses := cl.dispatch(ctx, bulkRequest)
+
+	stream := make(chan StreamedResponse)
+	go cl.streamListener(ctx, bulkRequest, processedResponses, stream)
+
+	return stream, nil
+}
 
-	for mWorker := 0; mWorker < 10; mWorker++ {
-		go cl.processRequests(ctx, recievedResponses, processedResponses)
+//dispatch sizes bulkRequest's response/error slices, starts the fire and
+//process worker pools and feeds every request into them, returning the
+//channel that the completion listener (Do) or stream listener (DoStream)
+//drains as responses become available. Every goroutine it starts exits once
+//ctx is done or its upstream channel is drained and closed, and the returned
+//channel is closed once nothing more will be sent on it, so callers can range
+//over it instead of having to know the request count up front.
+func (cl *BulkClient) dispatch(ctx context.Context, bulkRequest *RoundTrip) <-chan responseParcel {
+	noOfRequests := len(bulkRequest.requests)
+
+	bulkRequest.responses = make([]*http.Response, noOfRequests)
+	bulkRequest.errors = make([]error, noOfRequests)
+	bulkRequest.reqCancels = make([]context.CancelFunc, noOfRequests)
+
+	requestList := make(chan requestParcel, cl.queueSize)
+	recievedResponses := make(chan responseParcel, cl.queueSize)
+	processedResponses := make(chan responseParcel, cl.queueSize)
+
+	var fireWorkers, processWorkers sync.WaitGroup
+
+	fireWorkers.Add(cl.fireWorkers)
+	for nWorker := 0; nWorker < cl.fireWorkers; nWorker++ {
+		go func() {
+			defer fireWorkers.Done()
+			cl.fireRequests(ctx, requestList, recievedResponses)
+		}()
 	}
 
-	for index, req := range bulkRequest.requests {
-		bulkRequest.requests[index] = req.WithContext(ctx)
-		reqParcel := requestParcel{
-			request: bulkRequest.requests[index],
-			index: index,
+	processWorkers.Add(cl.processWorkers)
+	for mWorker := 0; mWorker < cl.processWorkers; mWorker++ {
+		go func() {
+			defer processWorkers.Done()
+			cl.processRequests(ctx, recievedResponses, processedResponses)
+		}()
+	}
+
+	var feeder sync.WaitGroup
+	feeder.Add(1)
+	go func() {
+		defer feeder.Done()
+		defer close(requestList)
+
+		for index, req := range bulkRequest.requests {
+			req = req.WithContext(ctx)
+			bulkRequest.requests[index] = req
+
+			var cacheKey string
+			if cl.cache != nil && cl.cachePolicy.eligible(req) {
+				cacheKey = cl.cachePolicy.cacheKey(req)
+
+				if cached, ok := cl.freshFromCache(ctx, req, cacheKey); ok {
+					select {
+					case processedResponses <- responseParcel{response: cached, index: index}:
+					case <-ctx.Done():
+						return
+					}
+					continue
+				}
+			}
+
+			reqParcel := requestParcel{
+				request:     req,
+				index:       index,
+				retryPolicy: bulkRequest.retryPolicyForIndex(index),
+				cacheKey:    cacheKey,
+				timeout:     bulkRequest.timeoutForIndex(index),
+			}
+
+			select {
+			case requestList <- reqParcel:
+			case <-ctx.Done():
+				return
+			}
 		}
+	}()
+
+	go func() {
+		fireWorkers.Wait()
+		close(recievedResponses)
+
+		processWorkers.Wait()
+		feeder.Wait()
+		close(processedResponses)
+	}()
 
-		requestList <- reqParcel
+	return processedResponses
+}
+
+// freshFromCache returns a ready-to-use http.Response built from the cached
+// entry for key, if one exists and is still within its freshness lifetime.
+func (cl *BulkClient) freshFromCache(ctx context.Context, req *http.Request, key string) (*http.Response, bool) {
+	body, headers, statusCode, found, err := cl.cache.Get(ctx, key)
+	if err != nil || !found {
+		return nil, false
 	}
 
-	return cl.completionListener(ctx, bulkRequest, processedResponses)
+	maxAge, known := freshFor(headers)
+	if !known || maxAge <= 0 || time.Since(responseDate(headers)) >= maxAge {
+		return nil, false
+	}
+
+	return cachedResponse(req.WithContext(context.Background()), body, headers, statusCode), true
 }
 
-//CloseAllResponses ...
+func cachedResponse(req *http.Request, body []byte, headers http.Header, statusCode int) *http.Response {
+	return &http.Response{
+		StatusCode: statusCode,
+		Status:     http.StatusText(statusCode),
+		Header:     headers.Clone(),
+		Body:       ioutil.NopCloser(bytes.NewReader(body)),
+		Request:    req,
+	}
+}
+
+// CloseAllResponses closes every response body on the RoundTrip, including
+// ones left unbuffered by WithStreamingBodies, and releases the context
+// Do/DoStream created internally (if any) along with any per-request timeout
+// contexts from AddRequestWithTimeout. Callers should call this once they
+// are done reading responses.
 func (r *RoundTrip) CloseAllResponses() {
 	for _, response := range r.responses {
 		if response != nil {
 			response.Body.Close()
 		}
 	}
+
+	for _, cancel := range r.reqCancels {
+		if cancel != nil {
+			cancel()
+		}
+	}
+
+	if r.cancel != nil {
+		r.cancel()
+	}
 }
 
 func (cl *BulkClient) completionListener(ctx context.Context, bulkRequest *RoundTrip, processedResponses <-chan responseParcel) ([]*http.Response, []error) {
-	LOOP:
+LOOP:
 	for done := 0; done < len(bulkRequest.requests); {
 		select {
 		case <-ctx.Done():
 			break LOOP
-		case resParcel := <-processedResponses:
+		case resParcel, ok := <-processedResponses:
+			if !ok {
+				break LOOP
+			}
+
+			bulkRequest.reqCancels[resParcel.index] = resParcel.cancel
+
 			if resParcel.err != nil {
 				bulkRequest.updateErrorForIndex(resParcel.err, resParcel.index)
 			} else {
@@ -141,6 +363,43 @@ func (cl *BulkClient) completionListener(ctx context.Context, bulkRequest *Round
 	return bulkRequest.responses, bulkRequest.errors
 }
 
+func (cl *BulkClient) streamListener(ctx context.Context, bulkRequest *RoundTrip, processedResponses <-chan responseParcel, stream chan<- StreamedResponse) {
+	defer close(stream)
+
+	sent := make([]bool, len(bulkRequest.requests))
+
+LOOP:
+	for done := 0; done < len(bulkRequest.requests); {
+		select {
+		case <-ctx.Done():
+			break LOOP
+		case resParcel, ok := <-processedResponses:
+			if !ok {
+				break LOOP
+			}
+
+			bulkRequest.reqCancels[resParcel.index] = resParcel.cancel
+
+			if resParcel.err != nil {
+				bulkRequest.updateErrorForIndex(resParcel.err, resParcel.index)
+			} else {
+				bulkRequest.updateResponseForIndex(resParcel.response, resParcel.index)
+			}
+
+			sent[resParcel.index] = true
+			stream <- StreamedResponse{Index: resParcel.index, Response: resParcel.response, Err: resParcel.err}
+			done++
+		}
+	}
+
+	bulkRequest.addRequestIgnoredErrors()
+	for i, wasSent := range sent {
+		if !wasSent {
+			stream <- StreamedResponse{Index: i, Err: bulkRequest.errors[i]}
+		}
+	}
+}
+
 func (r *RoundTrip) addRequestIgnoredErrors() {
 	for i, response := range r.responses {
 		if response == nil && r.errors[i] == nil {
@@ -161,84 +420,254 @@ func (r *RoundTrip) updateErrorForIndex(err error, index int) *RoundTrip {
 	return r
 }
 
-func (cl *BulkClient) fireRequests(reqList <-chan requestParcel, receivedResponses chan<- responseParcel) {
-	LOOP:
+func (cl *BulkClient) fireRequests(ctx context.Context, reqList <-chan requestParcel, receivedResponses chan<- responseParcel) {
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case reqParcel, isChanOpen := <-reqList:
 			if !isChanOpen {
-				break LOOP
+				return
 			}
 
-			receivedResponses <- cl.executeRequest(reqParcel)
+			select {
+			case receivedResponses <- cl.executeRequest(ctx, reqParcel):
+			case <-ctx.Done():
+				return
+			}
 		}
 	}
 }
 
-func (cl *BulkClient) executeRequest(reqParcel requestParcel) responseParcel {
-	resp, err := cl.httpclient.Do(reqParcel.request)
+func (cl *BulkClient) executeRequest(ctx context.Context, reqParcel requestParcel) responseParcel {
+	// cancel releases the per-request timeout context, if any. It must not
+	// fire until the response body has actually been read (by parseResponse)
+	// or closed (by RoundTrip.CloseAllResponses) — cancelling it as soon as
+	// executeRequest returns would abort an in-flight body read that happens
+	// later, on a different goroutine, so it rides along in responseParcel
+	// instead of being deferred here.
+	var cancel context.CancelFunc = func() {}
+	if reqParcel.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, reqParcel.timeout)
+		reqParcel.request = reqParcel.request.WithContext(ctx)
+	}
+
+	if cl.hostLimiter != nil {
+		release, err := cl.hostLimiter.acquire(ctx, reqParcel.request.URL.Host)
+		if err != nil {
+			return responseParcel{req: reqParcel.request, err: err, index: reqParcel.index, cancel: cancel}
+		}
+		defer release()
+	}
+
+	policy := reqParcel.retryPolicy
+	if policy == nil {
+		policy = &cl.retryPolicy
+	}
+
+	maxAttempts := policy.maxAttempts()
+
+	req := reqParcel.request
+	var bodyBytes []byte
+	if maxAttempts > 1 && req.Body != nil {
+		b, err := ioutil.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return responseParcel{req: req, err: fmt.Errorf("error buffering request body for retry: %s", err), index: reqParcel.index, cancel: cancel}
+		}
+
+		bodyBytes = b
+		req.Body = ioutil.NopCloser(bytes.NewReader(bodyBytes))
+	}
+
+	if cl.cache != nil && reqParcel.cacheKey != "" {
+		cl.attachConditionalHeaders(ctx, req, reqParcel.cacheKey)
+	}
+
+	var resp *http.Response
+	var err error
+
+	cl.observer.RequestStarted(reqParcel.index, req)
+	start := time.Now()
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		attemptReq := req
+		if attempt > 0 {
+			attemptReq = cloneRequestWithBody(req, bodyBytes)
+			cl.observer.RequestRetried(reqParcel.index, attemptReq, attempt)
+		}
+
+		resp, err = cl.httpclient.Do(attemptReq)
+		req = attemptReq
+
+		if attempt == maxAttempts-1 || !policy.shouldRetry()(resp, err) {
+			break
+		}
+
+		wait, ok := retryAfterDelay(resp)
+		if !ok {
+			wait = backoffDuration(policy, attempt)
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-req.Context().Done():
+			timer.Stop()
+			cl.observer.RequestFinished(reqParcel.index, req, time.Since(start), req.Context().Err())
+			return responseParcel{req: req, err: req.Context().Err(), index: reqParcel.index, cancel: cancel}
+		case <-timer.C:
+		}
+	}
+
+	cl.observer.RequestFinished(reqParcel.index, req, time.Since(start), err)
+
+	if err == nil && resp.StatusCode == http.StatusNotModified && reqParcel.cacheKey != "" {
+		if cached, ok := cl.replayFromCache(ctx, req, reqParcel.cacheKey); ok {
+			resp.Body.Close()
+			resp = cached
+		}
+	}
 
 	return responseParcel{
-		req:      reqParcel.request,
+		req:      req,
 		response: resp,
 		err:      err,
 		index:    reqParcel.index,
+		cacheKey: reqParcel.cacheKey,
+		cancel:   cancel,
+	}
+}
+
+// attachConditionalHeaders sets If-None-Match/If-Modified-Since on req from
+// the stored ETag/Last-Modified of a stale cache entry, so the server can
+// answer with 304 Not Modified instead of resending the body.
+func (cl *BulkClient) attachConditionalHeaders(ctx context.Context, req *http.Request, key string) {
+	_, headers, _, found, err := cl.cache.Get(ctx, key)
+	if err != nil || !found {
+		return
+	}
+
+	if etag := headers.Get("ETag"); etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+
+	if lastModified := headers.Get("Last-Modified"); lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+}
+
+// storeInCache writes a successful response to the cache, best-effort: a
+// cache write failure must not fail the request it came from.
+func (cl *BulkClient) storeInCache(ctx context.Context, key string, body []byte, headers http.Header, statusCode int) {
+	if !storable(headers) {
+		return
+	}
+
+	headers = headers.Clone()
+	if headers.Get("Date") == "" {
+		headers.Set("Date", time.Now().UTC().Format(http.TimeFormat))
+	}
+
+	ttl := cl.cachePolicy.DefaultTTL
+	if maxAge, known := freshFor(headers); known && maxAge > 0 {
+		ttl = maxAge
+	}
+
+	_ = cl.cache.Set(ctx, key, body, headers, statusCode, ttl)
+}
+
+// replayFromCache rebuilds the cached response for a 304 Not Modified reply.
+func (cl *BulkClient) replayFromCache(ctx context.Context, req *http.Request, key string) (*http.Response, bool) {
+	body, headers, statusCode, found, err := cl.cache.Get(ctx, key)
+	if err != nil || !found {
+		return nil, false
 	}
+
+	return cachedResponse(req, body, headers, statusCode), true
 }
 
 func (cl *BulkClient) processRequests(ctx context.Context, resList <-chan responseParcel, processedResponses chan<- responseParcel) {
-	LOOP:
 	for {
 		select {
+		case <-ctx.Done():
+			return
 		case resParcel, isChanOpen := <-resList:
 			if !isChanOpen {
-				break LOOP
+				return
 			}
 
-			processedResponses <- cl.parseResponse(ctx, resParcel)
+			select {
+			case processedResponses <- cl.parseResponse(ctx, resParcel):
+			case <-ctx.Done():
+				return
+			}
 		}
 	}
 }
 
 func (cl *BulkClient) parseResponse(ctx context.Context, res responseParcel) responseParcel {
-	defer func() {
+	reqCtx := ctx
+	if res.req != nil {
+		reqCtx = res.req.Context()
+	}
+
+	if res.err != nil && (errors.Is(reqCtx.Err(), context.Canceled) || errors.Is(reqCtx.Err(), context.DeadlineExceeded)) {
 		if res.response != nil {
 			res.response.Body.Close()
 		}
-	}()
-
-	if res.err != nil && (ctx.Err() == context.Canceled || ctx.Err() == context.DeadlineExceeded) {
-		return responseParcel{err: ErrRequestIgnored, index: res.index}
+		cl.observer.RequestIgnored(res.index, res.req)
+		return responseParcel{err: ErrRequestIgnored, index: res.index, cancel: res.cancel}
 	}
 
 	if res.err != nil {
-		return responseParcel{err: fmt.Errorf("http client error: %s", res.err), index: res.index}
+		if res.response != nil {
+			res.response.Body.Close()
+		}
+		return responseParcel{err: fmt.Errorf("http client error: %s", res.err), index: res.index, cancel: res.cancel}
 	}
 
 	if res.response == nil {
-		return responseParcel{err: errors.New("no response received"), index: res.index}
+		return responseParcel{err: errors.New("no response received"), index: res.index, cancel: res.cancel}
 	}
 
+	// Large or unknown-length bodies are handed to the caller unread, so the
+	// connection is streamed straight through instead of buffered in memory.
+	// The caller (normally via RoundTrip.CloseAllResponses) owns closing it,
+	// and owns releasing the per-request timeout context along with it — it
+	// must not be cancelled here, while the body is still unread.
+	if cl.shouldStreamBody(res.response) {
+		res.response.Request = res.req.WithContext(context.Background())
+		return responseParcel{response: res.response, index: res.index, cancel: res.cancel}
+	}
+
+	defer res.response.Body.Close()
+
 	bs, err := ioutil.ReadAll(res.response.Body)
 	if err != nil {
-		return responseParcel{err: fmt.Errorf("error while reading response body: %s", err), index: res.index}
+		return responseParcel{err: fmt.Errorf("error while reading response body: %s", err), index: res.index, cancel: res.cancel}
 	}
 
-	body := ioutil.NopCloser(bytes.NewReader(bs))
+	if cl.cache != nil && res.cacheKey != "" && res.response.StatusCode < 400 {
+		cl.storeInCache(ctx, res.cacheKey, bs, res.response.Header, res.response.StatusCode)
+	}
 
 	newResponse := http.Response{
-		Body:       body,
+		Body:       ioutil.NopCloser(bytes.NewReader(bs)),
 		StatusCode: res.response.StatusCode,
 		Status:     res.response.Status,
 		Header:     res.response.Header,
 		Request:    res.req.WithContext(context.Background()),
 	}
 
-	result := responseParcel{
-		response: &newResponse,
-		err:      err,
-		index:    res.index,
+	// The body is fully buffered above, so the per-request timeout context
+	// (if any) can be released now instead of waiting for CloseAllResponses.
+	if res.cancel != nil {
+		res.cancel()
 	}
 
-	return result
+	return responseParcel{response: &newResponse, index: res.index}
 }
@@ -0,0 +1,88 @@
+package bulkhttpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type sleepyHTTPClient struct {
+	delay map[string]time.Duration
+}
+
+func (c *sleepyHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	if d, ok := c.delay[req.URL.Path]; ok {
+		time.Sleep(d)
+	}
+	return http.DefaultClient.Do(req)
+}
+
+func TestDoStream_FastResponsesArriveBeforeSlow(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	fastReq, _ := http.NewRequest(http.MethodGet, server.URL+"/fast", nil)
+	slowReq, _ := http.NewRequest(http.MethodGet, server.URL+"/slow", nil)
+
+	client := &sleepyHTTPClient{delay: map[string]time.Duration{"/slow": 100 * time.Millisecond}}
+	cl := NewBulkHTTPClient(client, time.Second)
+
+	bulkRequest := NewBulkRequest().AddRequest(slowReq).AddRequest(fastReq)
+
+	stream, err := cl.DoStream(context.Background(), bulkRequest)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	var order []int
+	for streamed := range stream {
+		if streamed.Err != nil {
+			t.Fatalf("unexpected error for index %d: %s", streamed.Index, streamed.Err)
+		}
+		order = append(order, streamed.Index)
+	}
+
+	if len(order) != 2 || order[0] != 1 || order[1] != 0 {
+		t.Fatalf("expected fast request (index 1) before slow request (index 0), got %v", order)
+	}
+
+	bulkRequest.CloseAllResponses()
+}
+
+func TestDoStream_ContextCancelledIgnoresOutstanding(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(100 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req1, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	req2, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second)
+	bulkRequest := NewBulkRequest().AddRequest(req1).AddRequest(req2)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	stream, err := cl.DoStream(ctx, bulkRequest)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	count := 0
+	for streamed := range stream {
+		count++
+		if streamed.Err != ErrRequestIgnored {
+			t.Fatalf("expected ErrRequestIgnored for index %d, got %v", streamed.Index, streamed.Err)
+		}
+	}
+
+	if count != 2 {
+		t.Fatalf("expected 2 ignored responses, got %d", count)
+	}
+}
@@ -0,0 +1,101 @@
+package bulkhttpclient
+
+import (
+	"container/list"
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//InMemoryCache is a CacheStore backed by an in-process LRU, suitable as the
+//default store passed to WithCache for a single instance. Entries beyond
+//capacity are evicted least-recently-used first; entries past their TTL are
+//evicted lazily on Get.
+type InMemoryCache struct {
+	capacity int
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type memoryCacheEntry struct {
+	key        string
+	body       []byte
+	headers    http.Header
+	statusCode int
+	expiresAt  time.Time // zero means no expiration
+}
+
+//NewInMemoryCache creates an InMemoryCache holding at most capacity entries.
+//A capacity of zero or less means no limit.
+func NewInMemoryCache(capacity int) *InMemoryCache {
+	return &InMemoryCache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+//Get implements CacheStore.
+func (c *InMemoryCache) Get(_ context.Context, key string) ([]byte, http.Header, int, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.items[key]
+	if !ok {
+		return nil, nil, 0, false, nil
+	}
+
+	entry := elem.Value.(*memoryCacheEntry)
+	if !entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt) {
+		c.ll.Remove(elem)
+		delete(c.items, key)
+		return nil, nil, 0, false, nil
+	}
+
+	c.ll.MoveToFront(elem)
+
+	return entry.body, entry.headers.Clone(), entry.statusCode, true, nil
+}
+
+//Set implements CacheStore.
+func (c *InMemoryCache) Set(_ context.Context, key string, body []byte, headers http.Header, statusCode int, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl)
+	}
+
+	if elem, ok := c.items[key]; ok {
+		entry := elem.Value.(*memoryCacheEntry)
+		entry.body = body
+		entry.headers = headers.Clone()
+		entry.statusCode = statusCode
+		entry.expiresAt = expiresAt
+		c.ll.MoveToFront(elem)
+		return nil
+	}
+
+	elem := c.ll.PushFront(&memoryCacheEntry{
+		key:        key,
+		body:       body,
+		headers:    headers.Clone(),
+		statusCode: statusCode,
+		expiresAt:  expiresAt,
+	})
+	c.items[key] = elem
+
+	if c.capacity > 0 && c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		if oldest != nil {
+			c.ll.Remove(oldest)
+			delete(c.items, oldest.Value.(*memoryCacheEntry).key)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package bulkhttpclient
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+)
+
+//defaultWorkers is the fire/process pool size used when WithWorkers is not supplied.
+const defaultWorkers = 10
+
+//Observer receives lifecycle events for every request executed by a
+//BulkClient, so callers can wire up metrics (Prometheus, OpenTelemetry, ...)
+//without this module depending on them.
+type Observer interface {
+	RequestStarted(index int, req *http.Request)
+	RequestRetried(index int, req *http.Request, attempt int)
+	RequestIgnored(index int, req *http.Request)
+	RequestFinished(index int, req *http.Request, duration time.Duration, err error)
+}
+
+type noopObserver struct{}
+
+func (noopObserver) RequestStarted(int, *http.Request)                        {}
+func (noopObserver) RequestRetried(int, *http.Request, int)                   {}
+func (noopObserver) RequestIgnored(int, *http.Request)                        {}
+func (noopObserver) RequestFinished(int, *http.Request, time.Duration, error) {}
+
+//WithWorkers sets the number of goroutines used for firing requests and for
+//processing their responses. Defaults to 10 for each pool.
+func WithWorkers(fire, process int) Option {
+	return func(cl *BulkClient) {
+		cl.fireWorkers = fire
+		cl.processWorkers = process
+	}
+}
+
+//WithQueueSize sets the buffer size of the channels connecting the fire and
+//process worker pools. Defaults to 0 (unbuffered).
+func WithQueueSize(n int) Option {
+	return func(cl *BulkClient) {
+		cl.queueSize = n
+	}
+}
+
+//WithMaxInflightPerHost caps the number of concurrent requests executed
+//against any single request.URL.Host, so one slow host cannot starve the
+//rest of the pool.
+func WithMaxInflightPerHost(n int) Option {
+	return func(cl *BulkClient) {
+		cl.hostLimiter = newHostLimiter(n)
+	}
+}
+
+//WithObserver wires an Observer to receive request lifecycle events.
+func WithObserver(observer Observer) Option {
+	return func(cl *BulkClient) {
+		cl.observer = observer
+	}
+}
+
+//hostLimiter bounds concurrent in-flight requests per host using a counting
+//semaphore per host, created lazily on first use.
+type hostLimiter struct {
+	limit int
+
+	mu   sync.Mutex
+	sems map[string]chan struct{}
+}
+
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{
+		limit: limit,
+		sems:  make(map[string]chan struct{}),
+	}
+}
+
+func (h *hostLimiter) semaphore(host string) chan struct{} {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	sem, ok := h.sems[host]
+	if !ok {
+		sem = make(chan struct{}, h.limit)
+		h.sems[host] = sem
+	}
+
+	return sem
+}
+
+//acquire blocks until a slot for host is available, or ctx is done, and
+//returns a func that releases the slot.
+func (h *hostLimiter) acquire(ctx context.Context, host string) (func(), error) {
+	sem := h.semaphore(host)
+
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
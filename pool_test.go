@@ -0,0 +1,190 @@
+package bulkhttpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingObserver records how many times each Observer callback fires, and
+// the highest retry attempt seen, so tests can assert on them.
+type countingObserver struct {
+	started, retried, ignored, finished int32
+	maxAttemptSeen                      int32
+}
+
+func (o *countingObserver) RequestStarted(int, *http.Request) {
+	atomic.AddInt32(&o.started, 1)
+}
+
+func (o *countingObserver) RequestRetried(_ int, _ *http.Request, attempt int) {
+	atomic.AddInt32(&o.retried, 1)
+	for {
+		seen := atomic.LoadInt32(&o.maxAttemptSeen)
+		if int32(attempt) <= seen || atomic.CompareAndSwapInt32(&o.maxAttemptSeen, seen, int32(attempt)) {
+			break
+		}
+	}
+}
+
+func (o *countingObserver) RequestIgnored(int, *http.Request) {
+	atomic.AddInt32(&o.ignored, 1)
+}
+
+func (o *countingObserver) RequestFinished(int, *http.Request, time.Duration, error) {
+	atomic.AddInt32(&o.finished, 1)
+}
+
+func TestDo_MaxInflightPerHost(t *testing.T) {
+	var current, maxSeen int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&current, 1)
+		for {
+			seen := atomic.LoadInt32(&maxSeen)
+			if n <= seen || atomic.CompareAndSwapInt32(&maxSeen, seen, n) {
+				break
+			}
+		}
+
+		time.Sleep(5 * time.Millisecond)
+		atomic.AddInt32(&current, -1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cl := NewBulkHTTPClient(http.DefaultClient, 5*time.Second, WithMaxInflightPerHost(1))
+
+	bulkRequest := NewBulkRequest()
+	for i := 0; i < 50; i++ {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		bulkRequest.AddRequest(req)
+	}
+
+	_, errs := cl.Do(bulkRequest)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected error for request %d: %s", i, err)
+		}
+	}
+
+	if atomic.LoadInt32(&maxSeen) != 1 {
+		t.Fatalf("expected exactly 1 concurrent request, saw %d", maxSeen)
+	}
+
+	bulkRequest.CloseAllResponses()
+}
+
+// TestDo_WorkersDoNotLeakAcrossCalls guards against dispatch's worker pools
+// (fireRequests/processRequests) blocking forever on channels that are never
+// closed: every Do call's fireWorkers/processWorkers should exit once the
+// call returns, not pile up goroutines call after call.
+func TestDo_WorkersDoNotLeakAcrossCalls(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second)
+
+	runOnce := func() {
+		req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+		bulkRequest := NewBulkRequest().AddRequest(req)
+		if _, errs := cl.Do(bulkRequest); errs[0] != nil {
+			t.Fatalf("unexpected error: %s", errs[0])
+		}
+		bulkRequest.CloseAllResponses()
+	}
+
+	// Warm up once so the baseline below doesn't include one-time setup
+	// goroutines (e.g. the http.Transport's idle-conn reaper).
+	runOnce()
+	runtime.GC()
+	time.Sleep(50 * time.Millisecond)
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 20; i++ {
+		runOnce()
+	}
+
+	runtime.GC()
+	time.Sleep(50 * time.Millisecond)
+	after := runtime.NumGoroutine()
+
+	if after > before+5 {
+		t.Fatalf("goroutine count grew from %d to %d after 20 Do calls, workers are leaking", before, after)
+	}
+}
+
+func TestDo_ObserverReceivesLifecycleEvents(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	observer := &countingObserver{}
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second, WithObserver(observer), WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+	}))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	bulkRequest := NewBulkRequest().AddRequest(req)
+
+	_, errs := cl.Do(bulkRequest)
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+	bulkRequest.CloseAllResponses()
+
+	if got := atomic.LoadInt32(&observer.started); got != 1 {
+		t.Fatalf("expected RequestStarted once, got %d", got)
+	}
+
+	if got := atomic.LoadInt32(&observer.retried); got != 1 {
+		t.Fatalf("expected RequestRetried once, got %d", got)
+	}
+
+	if got := atomic.LoadInt32(&observer.maxAttemptSeen); got != 1 {
+		t.Fatalf("expected RequestRetried to report attempt 1, got %d", got)
+	}
+
+	if got := atomic.LoadInt32(&observer.finished); got != 1 {
+		t.Fatalf("expected RequestFinished once, got %d", got)
+	}
+
+	if got := atomic.LoadInt32(&observer.ignored); got != 0 {
+		t.Fatalf("expected RequestIgnored never to fire on a normal request, got %d", got)
+	}
+
+	// A request that times out mid-flight is reported as ignored, and
+	// RequestIgnored should fire for it (RequestFinished also fires for it,
+	// carrying the timeout error).
+	slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer slowServer.Close()
+
+	slowReq, _ := http.NewRequest(http.MethodGet, slowServer.URL, nil)
+	ignoredRequest := NewBulkRequest().AddRequestWithTimeout(slowReq, 5*time.Millisecond)
+	_, errs = cl.DoWithContext(context.Background(), ignoredRequest)
+	if errs[0] != ErrRequestIgnored {
+		t.Fatalf("expected ErrRequestIgnored, got %s", errs[0])
+	}
+	ignoredRequest.CloseAllResponses()
+
+	if got := atomic.LoadInt32(&observer.ignored); got != 1 {
+		t.Fatalf("expected RequestIgnored once, got %d", got)
+	}
+}
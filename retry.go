@@ -0,0 +1,121 @@
+package bulkhttpclient
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+//RetryPolicy controls how a request is retried by executeRequest. A zero
+//value RetryPolicy behaves as a single attempt (no retries).
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Multiplier     float64
+	ShouldRetry    func(*http.Response, error) bool
+}
+
+//WithRetryPolicy sets the RetryPolicy applied to every request that does not
+//override it via RoundTrip.AddRequestWithRetry.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(cl *BulkClient) {
+		cl.retryPolicy = policy
+	}
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) shouldRetry() func(*http.Response, error) bool {
+	if p != nil && p.ShouldRetry != nil {
+		return p.ShouldRetry
+	}
+	return defaultShouldRetry
+}
+
+func defaultShouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+
+	if resp == nil {
+		return false
+	}
+
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+}
+
+//backoffDuration computes a full-jitter exponential backoff: a random
+//duration between 0 and min(MaxBackoff, InitialBackoff*Multiplier^attempt).
+func backoffDuration(policy *RetryPolicy, attempt int) time.Duration {
+	multiplier := policy.Multiplier
+	if multiplier <= 0 {
+		multiplier = 2.0
+	}
+
+	backoff := float64(policy.InitialBackoff) * math.Pow(multiplier, float64(attempt))
+	if policy.MaxBackoff > 0 && backoff > float64(policy.MaxBackoff) {
+		backoff = float64(policy.MaxBackoff)
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	return time.Duration(rand.Float64() * backoff)
+}
+
+//retryAfterDelay reads the Retry-After header off 429/503 responses,
+//supporting both the delta-seconds and HTTP-date formats.
+func retryAfterDelay(resp *http.Response) (time.Duration, bool) {
+	if resp == nil {
+		return 0, false
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+		return 0, false
+	}
+
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if when, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(when); delay > 0 {
+			return delay, true
+		}
+		return 0, true
+	}
+
+	return 0, false
+}
+
+//cloneRequestWithBody clones req for a retry attempt, rewinding body to a
+//fresh reader over the buffered bytes so it can be sent again.
+func cloneRequestWithBody(req *http.Request, body []byte) *http.Request {
+	clone := req.Clone(req.Context())
+
+	if body != nil {
+		clone.Body = ioutil.NopCloser(bytes.NewReader(body))
+		clone.GetBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+
+	return clone
+}
@@ -0,0 +1,154 @@
+package bulkhttpclient
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestDo_RetriesOn5xxThenSucceeds(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second, WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     10 * time.Millisecond,
+		Multiplier:     2,
+	}))
+
+	bulkRequest := NewBulkRequest().AddRequest(req)
+	responses, errs := cl.Do(bulkRequest)
+
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+
+	if responses[0].StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", responses[0].StatusCode)
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 3 {
+		t.Fatalf("expected 3 attempts, got %d", got)
+	}
+
+	bulkRequest.CloseAllResponses()
+}
+
+func TestDo_PerRequestRetryOverride(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second)
+
+	policy := &RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond}
+	bulkRequest := NewBulkRequest().AddRequestWithRetry(req, policy)
+
+	responses, _ := cl.Do(bulkRequest)
+	if responses[0].StatusCode != http.StatusInternalServerError {
+		t.Fatalf("expected 500, got %d", responses[0].StatusCode)
+	}
+
+	if got := atomic.LoadInt32(&attempts); got != 2 {
+		t.Fatalf("expected 2 attempts, got %d", got)
+	}
+
+	bulkRequest.CloseAllResponses()
+}
+
+func TestDo_RetryAfterDeltaSecondsOverridesBackoff(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second, WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: 5 * time.Second,
+	}))
+
+	bulkRequest := NewBulkRequest().AddRequest(req)
+
+	start := time.Now()
+	responses, errs := cl.Do(bulkRequest)
+	elapsed := time.Since(start)
+
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+
+	if responses[0].StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", responses[0].StatusCode)
+	}
+
+	if elapsed >= 5*time.Second {
+		t.Fatalf("expected the delta-seconds Retry-After to override the 5s backoff, took %s", elapsed)
+	}
+
+	bulkRequest.CloseAllResponses()
+}
+
+func TestDo_RetryAfterHTTPDateOverridesBackoff(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.Header().Set("Retry-After", time.Now().Add(-time.Second).UTC().Format(http.TimeFormat))
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second, WithRetryPolicy(RetryPolicy{
+		MaxAttempts:    2,
+		InitialBackoff: 5 * time.Second,
+	}))
+
+	bulkRequest := NewBulkRequest().AddRequest(req)
+
+	start := time.Now()
+	responses, errs := cl.Do(bulkRequest)
+	elapsed := time.Since(start)
+
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+
+	if responses[0].StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", responses[0].StatusCode)
+	}
+
+	if elapsed >= 5*time.Second {
+		t.Fatalf("expected the HTTP-date Retry-After to override the 5s backoff, took %s", elapsed)
+	}
+
+	bulkRequest.CloseAllResponses()
+}
@@ -0,0 +1,30 @@
+package bulkhttpclient
+
+import "net/http"
+
+//WithStreamingBodies makes the BulkClient hand back response bodies unread
+//instead of buffering them in memory, for any response whose Content-Length
+//exceeds maxBuffered or is unknown (Content-Length == -1). This avoids both
+//OOMing on large downloads and holding the underlying connection idle while
+//the whole body is read into memory up front.
+//
+//Streamed bodies are not written to the cache (there is nothing buffered to
+//store) and, unlike buffered responses, are not closed automatically by the
+//client — the caller must close them, normally via RoundTrip.CloseAllResponses.
+func WithStreamingBodies(maxBuffered int64) Option {
+	return func(cl *BulkClient) {
+		cl.streamBodies = true
+		cl.maxBuffered = maxBuffered
+	}
+}
+
+//shouldStreamBody reports whether resp's body is large enough (or of
+//unknown length) that it should be left unbuffered rather than read fully
+//into memory by parseResponse.
+func (cl *BulkClient) shouldStreamBody(resp *http.Response) bool {
+	if !cl.streamBodies {
+		return false
+	}
+
+	return resp.ContentLength < 0 || resp.ContentLength > cl.maxBuffered
+}
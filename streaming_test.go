@@ -0,0 +1,82 @@
+package bulkhttpclient
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestDo_StreamsLargeBodiesWithoutFullyBuffering(t *testing.T) {
+	const payloadSize = 100 * 1024 * 1024
+	chunk := bytes.Repeat([]byte("a"), 1<<20)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", strconv.Itoa(payloadSize))
+		w.WriteHeader(http.StatusOK)
+		for written := 0; written < payloadSize; written += len(chunk) {
+			w.Write(chunk)
+		}
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	cl := NewBulkHTTPClient(http.DefaultClient, 10*time.Second, WithStreamingBodies(1<<20))
+
+	bulkRequest := NewBulkRequest().AddRequest(req)
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	responses, errs := cl.Do(bulkRequest)
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+
+	n, err := io.Copy(io.Discard, responses[0].Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading streamed body: %s", err)
+	}
+	if n != payloadSize {
+		t.Fatalf("expected to read %d bytes, got %d", payloadSize, n)
+	}
+
+	runtime.ReadMemStats(&after)
+	if grew := after.HeapAlloc - before.HeapAlloc; grew > payloadSize/2 {
+		t.Fatalf("expected streaming to keep heap growth well below the payload size, grew by %d bytes", grew)
+	}
+
+	bulkRequest.CloseAllResponses()
+}
+
+func TestDo_SmallBodyUnderThresholdIsStillBuffered(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("small body"))
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second, WithStreamingBodies(1<<20))
+
+	bulkRequest := NewBulkRequest().AddRequest(req)
+	responses, errs := cl.Do(bulkRequest)
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+
+	body, err := io.ReadAll(responses[0].Body)
+	if err != nil {
+		t.Fatalf("unexpected error reading body: %s", err)
+	}
+	if string(body) != "small body" {
+		t.Fatalf("expected %q, got %q", "small body", body)
+	}
+
+	bulkRequest.CloseAllResponses()
+}
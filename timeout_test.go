@@ -0,0 +1,100 @@
+package bulkhttpclient
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDoWithContext_PerRequestTimeoutDoesNotAffectSiblings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/slow" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	slowReq, _ := http.NewRequest(http.MethodGet, server.URL+"/slow", nil)
+	fastReq, _ := http.NewRequest(http.MethodGet, server.URL+"/fast", nil)
+
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second)
+
+	bulkRequest := NewBulkRequest().
+		AddRequestWithTimeout(slowReq, 5*time.Millisecond).
+		AddRequest(fastReq)
+
+	responses, errs := cl.DoWithContext(context.Background(), bulkRequest)
+
+	if errs[0] != ErrRequestIgnored {
+		t.Fatalf("expected the slow request to time out with ErrRequestIgnored, got %v", errs[0])
+	}
+
+	if errs[1] != nil {
+		t.Fatalf("expected the fast request to succeed despite its sibling timing out, got %s", errs[1])
+	}
+
+	if responses[1] == nil || responses[1].StatusCode != http.StatusOK {
+		t.Fatalf("expected fast request to get a 200, got %v", responses[1])
+	}
+
+	bulkRequest.CloseAllResponses()
+}
+
+func TestDoWithContext_PerRequestTimeoutBodyIsReadableAfterSuccess(t *testing.T) {
+	payload := bytes.Repeat([]byte("x"), 1<<20)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write(payload)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second)
+
+	bulkRequest := NewBulkRequest().AddRequestWithTimeout(req, 200*time.Millisecond)
+
+	responses, errs := cl.DoWithContext(context.Background(), bulkRequest)
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+
+	body, err := ioutil.ReadAll(responses[0].Body)
+	if err != nil {
+		t.Fatalf("expected the response body to still be readable, got: %s", err)
+	}
+
+	if len(body) != len(payload) {
+		t.Fatalf("expected to read %d bytes, got %d", len(payload), len(body))
+	}
+
+	bulkRequest.CloseAllResponses()
+}
+
+func TestDo_IsBackwardCompatible(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	cl := NewBulkHTTPClient(http.DefaultClient, time.Second)
+
+	bulkRequest := NewBulkRequest().AddRequest(req)
+	responses, errs := cl.Do(bulkRequest)
+
+	if errs[0] != nil {
+		t.Fatalf("unexpected error: %s", errs[0])
+	}
+
+	if responses[0].StatusCode != http.StatusOK {
+		t.Fatalf("expected 200, got %d", responses[0].StatusCode)
+	}
+
+	bulkRequest.CloseAllResponses()
+}